@@ -0,0 +1,73 @@
+package azuredns
+
+import (
+	"fmt"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	aauth "github.com/Azure/go-autorest/autorest/azure/auth"
+)
+
+// authMethod selects how this provider obtains an ARM bearer token.
+type authMethod string
+
+const (
+	// authMethodClientSecret is the original, and still default, mode:
+	// a service principal identified by ClientID/ClientSecret/TenantID.
+	authMethodClientSecret authMethod = "clientsecret"
+	// authMethodManagedIdentity uses the identity assigned to the host
+	// (e.g. an AKS pod with workload identity, or an Azure VM's MSI). Set
+	// ClientID to select a specific user-assigned identity.
+	authMethodManagedIdentity authMethod = "managedidentity"
+	// authMethodAzureCLI reuses the token cached by `az login`, for
+	// developer machines.
+	authMethodAzureCLI authMethod = "azurecli"
+	// authMethodEnvironment reads the standard AZURE_* environment
+	// variables (AZURE_CLIENT_ID, AZURE_TENANT_ID, AZURE_CLIENT_SECRET,
+	// AZURE_CLIENT_CERTIFICATE_PATH, ...), as used by Azure DevOps
+	// pipelines with a federated or classic service connection.
+	authMethodEnvironment authMethod = "environment"
+)
+
+// resolveEnvironment maps the Environment config key to the matching
+// azure.Environment, which in turn carries the ARM and AAD endpoints to
+// use for that cloud. It defaults to the public cloud.
+func resolveEnvironment(name string) (azure.Environment, error) {
+	if name == "" {
+		return azure.PublicCloud, nil
+	}
+	return azure.EnvironmentFromName(name)
+}
+
+// newAuthorizer builds an ARM authorizer according to m["AuthMethod"]
+// (default authMethodClientSecret), scoped to env's resource manager
+// endpoint.
+func newAuthorizer(m map[string]string, env azure.Environment) (autorest.Authorizer, error) {
+	method := authMethod(m["AuthMethod"])
+	if method == "" {
+		method = authMethodClientSecret
+	}
+
+	resource := env.ResourceManagerEndpoint
+
+	switch method {
+	case authMethodClientSecret:
+		cfg := aauth.NewClientCredentialsConfig(m["ClientID"], m["ClientSecret"], m["TenantID"])
+		cfg.AADEndpoint = env.ActiveDirectoryEndpoint
+		cfg.Resource = resource
+		return cfg.Authorizer()
+	case authMethodManagedIdentity:
+		cfg := aauth.NewMSIConfig()
+		cfg.Resource = resource
+		if clientID := m["ClientID"]; clientID != "" {
+			cfg.ClientID = clientID
+		}
+		return cfg.Authorizer()
+	case authMethodAzureCLI:
+		return aauth.NewAuthorizerFromCLIWithResource(resource)
+	case authMethodEnvironment:
+		return aauth.NewAuthorizerFromEnvironmentWithResource(resource)
+	default:
+		return nil, fmt.Errorf("AuthMethod %q not recognized - expected one of clientsecret, managedidentity, azurecli, environment", method)
+	}
+}
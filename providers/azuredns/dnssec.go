@@ -0,0 +1,281 @@
+package azuredns
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+)
+
+// The 2018-05-01 (public) and 2018-09-01 (private) Azure DNS SDKs predate
+// DS/DNSKEY/TLSA/NAPTR/SSHFP support and have no record types for them.
+// Until the generated SDKs catch up, this provider publishes them as TXT
+// record sets carrying a recognizable prefix, and decodes that prefix back
+// into the real record type on read - see the "DS", "DNSKEY", "TLSA",
+// "NAPTR", "SSHFP" cases threaded through nativeToRecords/recordToNative
+// and the generic*RecordSet converters in azureDnsProvider.go.
+const dnssecPassthroughPrefix = "dnscontrol-passthrough-v1:"
+
+// encodeDNSSECPassthroughRecord serializes rc into a single TXT value.
+func encodeDNSSECPassthroughRecord(rc *models.RecordConfig) string {
+	var fields []string
+	switch rc.Type {
+	case "DS":
+		fields = []string{
+			strconv.Itoa(int(rc.DsKeyTag)),
+			strconv.Itoa(int(rc.DsAlgorithm)),
+			strconv.Itoa(int(rc.DsDigestType)),
+			rc.DsDigest,
+		}
+	case "DNSKEY":
+		fields = []string{
+			strconv.Itoa(int(rc.DnskeyFlags)),
+			strconv.Itoa(int(rc.DnskeyProtocol)),
+			strconv.Itoa(int(rc.DnskeyAlgorithm)),
+			rc.DnskeyPublicKey,
+		}
+	case "TLSA":
+		fields = []string{
+			strconv.Itoa(int(rc.TlsaUsage)),
+			strconv.Itoa(int(rc.TlsaSelector)),
+			strconv.Itoa(int(rc.TlsaMatchingType)),
+			rc.Target,
+		}
+	case "NAPTR":
+		fields = []string{
+			strconv.Itoa(int(rc.NaptrOrder)),
+			strconv.Itoa(int(rc.NaptrPreference)),
+			rc.NaptrFlags,
+			rc.NaptrService,
+			rc.NaptrRegexp,
+			rc.Target,
+		}
+	case "SSHFP":
+		fields = []string{
+			strconv.Itoa(int(rc.SshfpAlgorithm)),
+			strconv.Itoa(int(rc.SshfpFingerprint)),
+			rc.Target,
+		}
+	default:
+		panic(errors.Errorf("encodeDNSSECPassthroughRecord: unsupported type %v", rc.Type))
+	}
+
+	escaped := make([]string, len(fields))
+	for i, f := range fields {
+		escaped[i] = escapeField(f)
+	}
+	return dnssecPassthroughPrefix + rc.Type + ":" + strings.Join(escaped, ":")
+}
+
+// escapeField escapes backslashes and colons in v so it can be safely
+// embedded as one colon-delimited field of a passthrough value. Free-text
+// fields like NaptrRegexp routinely contain colons themselves (e.g. the
+// ENUM/SIP pattern "!^.*$!sip:user@example.com!"), and a bare colon join
+// would make those values unparseable on the way back.
+func escapeField(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, ":", `\:`)
+	return v
+}
+
+// splitEscapedFields reverses the join performed by encodeDNSSECPassthroughRecord,
+// splitting s on unescaped colons and unescaping each resulting field.
+func splitEscapedFields(s string) []string {
+	var fields []string
+	var cur strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			fields = append(fields, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	fields = append(fields, cur.String())
+	return fields
+}
+
+// decodeDNSSECPassthroughRecord reverses encodeDNSSECPassthroughRecord. It
+// returns nil if txt isn't one of our encoded values, so callers can fall
+// back to treating the record as plain TXT.
+func decodeDNSSECPassthroughRecord(txt []string, fqdn, origin string, ttl int64) *models.RecordConfig {
+	if len(txt) != 1 {
+		return nil
+	}
+	rest := strings.TrimPrefix(txt[0], dnssecPassthroughPrefix)
+	if rest == txt[0] {
+		return nil
+	}
+	parts := strings.SplitN(rest, ":", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	recordType, encoded := parts[0], splitEscapedFields(parts[1])
+
+	rc := &models.RecordConfig{TTL: uint32(ttl)}
+	rc.SetLabelFromFQDN(fqdn, origin)
+	rc.Type = recordType
+
+	var err error
+	switch recordType {
+	case "DS":
+		if len(encoded) != 4 {
+			return nil
+		}
+		keyTag, e1 := strconv.Atoi(encoded[0])
+		algorithm, e2 := strconv.Atoi(encoded[1])
+		digestType, e3 := strconv.Atoi(encoded[2])
+		if e1 != nil || e2 != nil || e3 != nil {
+			return nil
+		}
+		rc.DsKeyTag = uint16(keyTag)
+		rc.DsAlgorithm = uint8(algorithm)
+		rc.DsDigestType = uint8(digestType)
+		rc.DsDigest = encoded[3]
+	case "DNSKEY":
+		if len(encoded) != 4 {
+			return nil
+		}
+		flags, e1 := strconv.Atoi(encoded[0])
+		protocol, e2 := strconv.Atoi(encoded[1])
+		algorithm, e3 := strconv.Atoi(encoded[2])
+		if e1 != nil || e2 != nil || e3 != nil {
+			return nil
+		}
+		rc.DnskeyFlags = uint16(flags)
+		rc.DnskeyProtocol = uint8(protocol)
+		rc.DnskeyAlgorithm = uint8(algorithm)
+		rc.DnskeyPublicKey = encoded[3]
+	case "TLSA":
+		if len(encoded) != 4 {
+			return nil
+		}
+		usage, e1 := strconv.Atoi(encoded[0])
+		selector, e2 := strconv.Atoi(encoded[1])
+		matchingType, e3 := strconv.Atoi(encoded[2])
+		if e1 != nil || e2 != nil || e3 != nil {
+			return nil
+		}
+		rc.TlsaUsage = uint8(usage)
+		rc.TlsaSelector = uint8(selector)
+		rc.TlsaMatchingType = uint8(matchingType)
+		err = rc.SetTarget(encoded[3])
+	case "NAPTR":
+		if len(encoded) != 6 {
+			return nil
+		}
+		order, e1 := strconv.Atoi(encoded[0])
+		preference, e2 := strconv.Atoi(encoded[1])
+		if e1 != nil || e2 != nil {
+			return nil
+		}
+		rc.NaptrOrder = uint16(order)
+		rc.NaptrPreference = uint16(preference)
+		rc.NaptrFlags = encoded[2]
+		rc.NaptrService = encoded[3]
+		rc.NaptrRegexp = encoded[4]
+		err = rc.SetTarget(encoded[5])
+	case "SSHFP":
+		if len(encoded) != 3 {
+			return nil
+		}
+		algorithm, e1 := strconv.Atoi(encoded[0])
+		fingerprint, e2 := strconv.Atoi(encoded[1])
+		if e1 != nil || e2 != nil {
+			return nil
+		}
+		rc.SshfpAlgorithm = uint8(algorithm)
+		rc.SshfpFingerprint = uint8(fingerprint)
+		err = rc.SetTarget(encoded[2])
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+	return rc
+}
+
+// overrideDNSSECPassthroughType rewrites rs.recordType from "TXT" to the
+// real DS/DNSKEY/TLSA/NAPTR/SSHFP type when every value in the set decodes
+// as a passthrough record, so the rest of the provider (diffing, deletion
+// matching) can treat it like any other native record type.
+func overrideDNSSECPassthroughType(rs *azureRecordSet) {
+	if rs.recordType != "TXT" || len(rs.txtRecords) == 0 {
+		return
+	}
+	var recordType string
+	for _, txt := range rs.txtRecords {
+		rc := decodeDNSSECPassthroughRecord(txt, rs.fqdn, rs.fqdn, rs.ttl)
+		if rc == nil {
+			return
+		}
+		thisType := rc.Type
+		if recordType == "" {
+			recordType = thisType
+		} else if recordType != thisType {
+			return
+		}
+	}
+	if recordType != "" {
+		rs.recordType = recordType
+	}
+}
+
+// EnableDNSSEC turns on DNSSEC signing for a public Azure DNS zone. The ARM
+// dnssecConfigs proxy resource isn't modeled by the 2018-05-01 SDK, so this
+// issues the request directly through the zones client's HTTP plumbing
+// instead of a generated method.
+func (a *azureDnsProvider) EnableDNSSEC(domain string) error {
+	if a.zoneType == zoneTypePrivate {
+		return fmt.Errorf("EnableDNSSEC: Azure Private DNS zones do not support DNSSEC")
+	}
+	zone, ok := a.zones[domain]
+	if !ok {
+		return errNoExist{domain}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 6000*time.Second)
+	defer cancel()
+
+	req, err := autorest.Prepare(&http.Request{},
+		autorest.AsPut(),
+		autorest.WithBaseURL(a.zonesClient.BaseURI),
+		autorest.WithPathParameters(
+			"/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Network/dnszones/{zoneName}/dnssecConfigs/default",
+			map[string]interface{}{
+				"subscriptionId":    a.zonesClient.SubscriptionID,
+				"resourceGroupName": *a.resourceGroup,
+				"zoneName":          zone.name,
+			},
+		),
+		autorest.WithQueryParameters(map[string]interface{}{"api-version": "2022-07-01"}),
+		autorest.WithJSON(struct{}{}),
+	)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := a.zonesClient.Send(req, azure.DoRetryWithRegistration(a.zonesClient.Client))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return autorest.Respond(resp,
+		azure.WithErrorUnlessStatusCode(http.StatusOK, http.StatusCreated, http.StatusAccepted))
+}
@@ -0,0 +1,101 @@
+package azuredns
+
+import (
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func TestSplitEscapedFieldsRoundTripsArbitraryColonsAndBackslashes(t *testing.T) {
+	cases := [][]string{
+		{"plain"},
+		{"has:colon"},
+		{"has\\backslash"},
+		{"has\\:both"},
+		{"!^.*$!sip:customer@example.com!", "trailing"},
+		{"", "empty-first-field"},
+		{"a", "", "c"},
+	}
+
+	for _, fields := range cases {
+		escaped := make([]string, len(fields))
+		for i, f := range fields {
+			escaped[i] = escapeField(f)
+		}
+		joined := ""
+		for i, e := range escaped {
+			if i > 0 {
+				joined += ":"
+			}
+			joined += e
+		}
+
+		got := splitEscapedFields(joined)
+		if len(got) != len(fields) {
+			t.Fatalf("splitEscapedFields(%q) = %q, want %d fields matching %q", joined, got, len(fields), fields)
+		}
+		for i := range fields {
+			if got[i] != fields[i] {
+				t.Errorf("splitEscapedFields(%q)[%d] = %q, want %q", joined, i, got[i], fields[i])
+			}
+		}
+	}
+}
+
+func TestDNSSECPassthroughRoundTripsNAPTRWithColonsInRegexp(t *testing.T) {
+	rc := &models.RecordConfig{
+		Type:            "NAPTR",
+		TTL:             300,
+		NaptrOrder:      100,
+		NaptrPreference: 10,
+		NaptrFlags:      "u",
+		NaptrService:    "E2U+sip",
+		NaptrRegexp:     "!^.*$!sip:customer@example.com!",
+		Target:          ".",
+	}
+
+	encoded := encodeDNSSECPassthroughRecord(rc)
+
+	decoded := decodeDNSSECPassthroughRecord([]string{encoded}, "naptr.example.com", "example.com", 300)
+	if decoded == nil {
+		t.Fatalf("decodeDNSSECPassthroughRecord returned nil for %q, want a decoded NAPTR record", encoded)
+	}
+	if decoded.NaptrRegexp != rc.NaptrRegexp {
+		t.Errorf("NaptrRegexp = %q, want %q", decoded.NaptrRegexp, rc.NaptrRegexp)
+	}
+	if decoded.NaptrOrder != rc.NaptrOrder || decoded.NaptrPreference != rc.NaptrPreference {
+		t.Errorf("NaptrOrder/NaptrPreference = %d/%d, want %d/%d",
+			decoded.NaptrOrder, decoded.NaptrPreference, rc.NaptrOrder, rc.NaptrPreference)
+	}
+	if decoded.NaptrFlags != rc.NaptrFlags || decoded.NaptrService != rc.NaptrService {
+		t.Errorf("NaptrFlags/NaptrService = %q/%q, want %q/%q",
+			decoded.NaptrFlags, decoded.NaptrService, rc.NaptrFlags, rc.NaptrService)
+	}
+}
+
+func TestDNSSECPassthroughRoundTripsDS(t *testing.T) {
+	rc := &models.RecordConfig{
+		Type:         "DS",
+		TTL:          300,
+		DsKeyTag:     12345,
+		DsAlgorithm:  13,
+		DsDigestType: 2,
+		DsDigest:     "abcdef0123456789",
+	}
+
+	encoded := encodeDNSSECPassthroughRecord(rc)
+	decoded := decodeDNSSECPassthroughRecord([]string{encoded}, "example.com", "example.com", 300)
+	if decoded == nil {
+		t.Fatalf("decodeDNSSECPassthroughRecord returned nil for %q, want a decoded DS record", encoded)
+	}
+	if decoded.DsKeyTag != rc.DsKeyTag || decoded.DsAlgorithm != rc.DsAlgorithm ||
+		decoded.DsDigestType != rc.DsDigestType || decoded.DsDigest != rc.DsDigest {
+		t.Errorf("decoded DS fields = %+v, want to match %+v", decoded, rc)
+	}
+}
+
+func TestDecodeDNSSECPassthroughRecordRejectsNonPassthroughTXT(t *testing.T) {
+	if got := decodeDNSSECPassthroughRecord([]string{"just a normal TXT value"}, "example.com", "example.com", 300); got != nil {
+		t.Errorf("decodeDNSSECPassthroughRecord = %+v, want nil for a non-passthrough TXT value", got)
+	}
+}
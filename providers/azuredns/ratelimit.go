@@ -0,0 +1,192 @@
+package azuredns
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// defaultMaxConcurrentRequests bounds how many ARM write requests this
+// provider will have in flight at once when MaxConcurrentRequests isn't
+// configured.
+const defaultMaxConcurrentRequests = 10
+
+// armWriteRateLimiter throttles outgoing record-set writes to stay under
+// ARM's documented DNS zone write limit (500 writes per 5 minutes per
+// zone). It's a plain token bucket: it refills at the configured rate and
+// blocks callers until a token is available.
+type armWriteRateLimiter struct {
+	mu         sync.Mutex
+	tokens     float64
+	max        float64
+	refillRate float64 // tokens per second
+	last       time.Time
+}
+
+// newARMWriteRateLimiter builds a limiter that allows maxWrites over the
+// given window, bursting up to the full window's allowance.
+func newARMWriteRateLimiter(maxWrites int, window time.Duration) *armWriteRateLimiter {
+	rate := float64(maxWrites) / window.Seconds()
+	return &armWriteRateLimiter{
+		tokens:     float64(maxWrites),
+		max:        float64(maxWrites),
+		refillRate: rate,
+		last:       timeNow(),
+	}
+}
+
+func (l *armWriteRateLimiter) wait() {
+	for {
+		l.mu.Lock()
+		now := timeNow()
+		elapsed := now.Sub(l.last).Seconds()
+		l.last = now
+		l.tokens += elapsed * l.refillRate
+		if l.tokens > l.max {
+			l.tokens = l.max
+		}
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return
+		}
+		deficit := 1 - l.tokens
+		wait := time.Duration(deficit/l.refillRate*1000) * time.Millisecond
+		l.mu.Unlock()
+		time.Sleep(wait)
+	}
+}
+
+// timeNow exists only so tests can override it; it's a thin wrapper around
+// time.Now.
+var timeNow = time.Now
+
+// correctionUnit is one independent piece of work produced while diffing a
+// zone: usually a single record-set create/update/delete. dependsOn lists
+// the indices (within the same units slice passed to runUnits) of units
+// that must finish first - e.g. a CNAME<->A/AAAA swap's create-unit depends
+// on the sibling delete-unit for the conflicting old type, so the two stay
+// ordered relative to each other while the unit as a whole still runs
+// concurrently with unrelated units.
+type correctionUnit struct {
+	msg       string
+	run       func() error
+	dependsOn []int
+}
+
+// rateLimiterFor returns the armWriteRateLimiter for zoneName, creating one
+// lazily on first use. ARM's 500-writes/5min DNS write quota is per zone,
+// not per subscription or provider instance, so a provider managing several
+// domains keeps one independent limiter per zone instead of sharing a
+// single budget across all of them.
+func (a *azureDnsProvider) rateLimiterFor(zoneName string) *armWriteRateLimiter {
+	a.rateLimitersMu.Lock()
+	defer a.rateLimitersMu.Unlock()
+	if a.rateLimiters == nil {
+		a.rateLimiters = map[string]*armWriteRateLimiter{}
+	}
+	limiter, ok := a.rateLimiters[zoneName]
+	if !ok {
+		// ARM documents a DNS write limit of 500 requests per 5 minutes per zone.
+		limiter = newARMWriteRateLimiter(500, 5*time.Minute)
+		a.rateLimiters[zoneName] = limiter
+	}
+	return limiter
+}
+
+// runUnits executes units with at most a.maxConcurrentRequests in flight at
+// once, retrying individual units on 429 Too Many Requests using the
+// Retry-After header Azure returns, and honoring zoneName's write rate
+// limiter so bursts of concurrent units don't blow through ARM's 500
+// writes/5min-per-zone quota. A unit whose dependsOn is non-empty blocks
+// until those units have finished (successfully) before it starts; waiting
+// on a dependency doesn't hold a semaphore slot, so it can't deadlock
+// against the concurrency cap.
+func (a *azureDnsProvider) runUnits(zoneName string, units []correctionUnit) error {
+	limiter := a.rateLimiterFor(zoneName)
+	sem := make(chan struct{}, a.maxConcurrentRequests)
+	errs := make([]error, len(units))
+	done := make([]chan struct{}, len(units))
+	for i := range units {
+		done[i] = make(chan struct{})
+	}
+	var wg sync.WaitGroup
+
+	for i, u := range units {
+		wg.Add(1)
+		go func(i int, u correctionUnit) {
+			defer wg.Done()
+			defer close(done[i])
+
+			for _, dep := range u.dependsOn {
+				<-done[dep]
+				if errs[dep] != nil {
+					errs[i] = fmt.Errorf("skipped: dependency failed: %w", errs[dep])
+					return
+				}
+			}
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[i] = a.runUnitWithRetry(limiter, u)
+		}(i, u)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runUnitWithRetry runs u.run, retrying on 429 using the server-provided
+// Retry-After delay (falling back to exponential backoff if absent).
+func (a *azureDnsProvider) runUnitWithRetry(limiter *armWriteRateLimiter, u correctionUnit) error {
+	const maxAttempts = 6
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		limiter.wait()
+		err := u.run()
+		if err == nil {
+			return nil
+		}
+		retryAfter, ok := retryAfterDelay(err)
+		if !ok {
+			return err
+		}
+		if retryAfter <= 0 {
+			retryAfter = time.Duration(1<<uint(attempt)) * 100 * time.Millisecond
+		}
+		time.Sleep(retryAfter)
+	}
+	limiter.wait()
+	return u.run()
+}
+
+// retryAfterDelay reports whether err is a 429 response from ARM and, if
+// so, how long the Retry-After header asked us to wait.
+func retryAfterDelay(err error) (time.Duration, bool) {
+	detailed, ok := err.(autorest.DetailedError)
+	if !ok {
+		return 0, false
+	}
+	if detailed.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if detailed.Response == nil {
+		return 0, true
+	}
+	header := detailed.Response.Header.Get("Retry-After")
+	if header == "" {
+		return 0, true
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	return 0, true
+}
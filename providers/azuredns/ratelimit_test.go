@@ -0,0 +1,101 @@
+package azuredns
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func withFakeClock(t *testing.T, now time.Time) func(advance time.Duration) {
+	orig := timeNow
+	t.Cleanup(func() { timeNow = orig })
+	timeNow = func() time.Time { return now }
+	return func(advance time.Duration) { now = now.Add(advance) }
+}
+
+func TestArmWriteRateLimiterBurstsUpToMax(t *testing.T) {
+	withFakeClock(t, time.Unix(0, 0))
+
+	l := newARMWriteRateLimiter(3, time.Second)
+	for i := 0; i < 3; i++ {
+		l.wait() // tokens start at the full burst allowance, so none of these block
+	}
+	if l.tokens >= 1 {
+		t.Fatalf("tokens = %v after draining the burst allowance, want < 1", l.tokens)
+	}
+}
+
+func TestArmWriteRateLimiterRefillsOverTime(t *testing.T) {
+	advance := withFakeClock(t, time.Unix(0, 0))
+
+	l := newARMWriteRateLimiter(2, time.Second) // refills at 2 tokens/sec
+	l.wait()
+	l.wait() // tokens now ~0
+
+	advance(500 * time.Millisecond) // should refill exactly 1 token
+
+	l.wait() // must not block: the refilled token covers this call
+	if l.tokens >= 1 {
+		t.Fatalf("tokens = %v after consuming the refilled token, want < 1", l.tokens)
+	}
+}
+
+func TestRunUnitsRespectsDependsOn(t *testing.T) {
+	a := &azureDnsProvider{maxConcurrentRequests: 4}
+
+	var depDone int32
+	units := []correctionUnit{
+		{
+			msg: "delete www/A",
+			run: func() error {
+				time.Sleep(10 * time.Millisecond)
+				atomic.StoreInt32(&depDone, 1)
+				return nil
+			},
+		},
+		{
+			msg:       "create www/CNAME",
+			dependsOn: []int{0},
+			run: func() error {
+				if atomic.LoadInt32(&depDone) != 1 {
+					return fmt.Errorf("ran before its dependency finished")
+				}
+				return nil
+			},
+		},
+	}
+
+	if err := a.runUnits("example.com", units); err != nil {
+		t.Fatalf("runUnits returned an error: %v", err)
+	}
+}
+
+func TestRunUnitsSkipsDependentsOfAFailedUnit(t *testing.T) {
+	a := &azureDnsProvider{maxConcurrentRequests: 4}
+
+	var dependentRan int32
+	units := []correctionUnit{
+		{
+			msg: "delete www/A",
+			run: func() error {
+				return fmt.Errorf("boom")
+			},
+		},
+		{
+			msg:       "create www/CNAME",
+			dependsOn: []int{0},
+			run: func() error {
+				atomic.StoreInt32(&dependentRan, 1)
+				return nil
+			},
+		},
+	}
+
+	if err := a.runUnits("example.com", units); err == nil {
+		t.Fatal("runUnits returned no error, want the dependency failure surfaced")
+	}
+	if atomic.LoadInt32(&dependentRan) != 0 {
+		t.Fatal("dependent unit ran despite its dependency failing")
+	}
+}
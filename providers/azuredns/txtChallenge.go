@@ -0,0 +1,213 @@
+package azuredns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+
+	adns "github.com/Azure/azure-sdk-for-go/services/dns/mgmt/2018-05-01/dns"
+	privatedns "github.com/Azure/azure-sdk-for-go/services/privatedns/mgmt/2018-09-01/privatedns"
+)
+
+// defaultMaxTXTUpdateRetries bounds how many read-modify-write attempts
+// UpdateTXTRecord makes in total, counting the first one, before giving up
+// on losing races to other writers (412 Precondition Failed on the ETag)
+// when MaxTXTUpdateRetries isn't configured.
+const defaultMaxTXTUpdateRetries = 5
+
+// UpdateTXTRecord performs a concurrency-safe read-modify-write of the TXT
+// record set at fqdn, suitable for ACME DNS-01 solvers or other callers
+// that need to add/remove a single challenge token without racing other
+// writers touching the same FQDN. It satisfies providers.DNSChallengeUpdater.
+func (a *azureDnsProvider) UpdateTXTRecord(ctx context.Context, fqdn string, mutate func(existing []string) []string) error {
+	_, recordName, zoneName, err := a.splitTXTFqdn(fqdn)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; attempt < a.maxTXTUpdateRetries; attempt++ {
+		existing, etag, err := a.getTXTRecordSet(ctx, zoneName, recordName)
+		if err != nil {
+			return err
+		}
+
+		desired := mutate(existing)
+
+		if len(desired) == 0 {
+			if etag == "" {
+				// Nothing to delete.
+				return nil
+			}
+			err = a.deleteTXTRecordSet(ctx, zoneName, recordName, etag)
+		} else if etag == "" {
+			// No existing record set: require it still be absent when the
+			// write lands, via If-None-Match instead of If-Match, so two
+			// concurrent creates can't silently clobber one another.
+			err = a.createTXTRecordSet(ctx, zoneName, recordName, desired)
+		} else {
+			err = a.putTXTRecordSet(ctx, zoneName, recordName, desired, etag)
+		}
+
+		if err == nil {
+			return nil
+		}
+		if !isPreconditionFailed(err) {
+			return err
+		}
+		// Someone else won the race; retry with fresh state after a short
+		// backoff so we don't immediately collide again.
+		time.Sleep(time.Duration(attempt+1) * 50 * time.Millisecond)
+	}
+
+	return fmt.Errorf("UpdateTXTRecord: gave up after %d attempts racing other writers for %s", a.maxTXTUpdateRetries, fqdn)
+}
+
+// splitTXTFqdn resolves fqdn to the most specific zone that contains it
+// (from a.zones) plus the relative record name within that zone. Ties are
+// broken by longest domain name, so a delegated child zone like
+// "sub.example.com" wins over its parent "example.com" when both are
+// managed.
+func (a *azureDnsProvider) splitTXTFqdn(fqdn string) (domain, recordName, zoneName string, err error) {
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	var best string
+	for d := range a.zones {
+		if fqdn != d && !strings.HasSuffix(fqdn, "."+d) {
+			continue
+		}
+		if len(d) > len(best) {
+			best = d
+		}
+	}
+	if best == "" {
+		return "", "", "", fmt.Errorf("UpdateTXTRecord: no managed zone found for %s", fqdn)
+	}
+	if fqdn == best {
+		return best, "@", a.zones[best].name, nil
+	}
+	return best, strings.TrimSuffix(fqdn, "."+best), a.zones[best].name, nil
+}
+
+// getTXTRecordSet fetches the current TXT values and ETag for recordName,
+// returning an empty slice and empty etag if the record set doesn't exist.
+func (a *azureDnsProvider) getTXTRecordSet(ctx context.Context, zoneName, recordName string) ([]string, string, error) {
+	if a.zoneType == zoneTypePrivate {
+		result, err := a.privateRecordsClient.Get(ctx, *a.resourceGroup, zoneName, privatedns.TXT, recordName)
+		if isNotFound(err) {
+			return nil, "", nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return flattenPrivateTXTValues(result.TxtRecords), etagString(result.Etag), nil
+	}
+
+	result, err := a.recordsClient.Get(ctx, *a.resourceGroup, zoneName, recordName, adns.TXT)
+	if isNotFound(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return flattenPublicTXTValues(result.TxtRecords), etagString(result.Etag), nil
+}
+
+// putTXTRecordSet overwrites an existing TXT record set, requiring it to
+// still match ifMatch (its last-seen ETag).
+func (a *azureDnsProvider) putTXTRecordSet(ctx context.Context, zoneName, recordName string, values []string, ifMatch string) error {
+	return a.writeTXTRecordSet(ctx, zoneName, recordName, values, ifMatch, "")
+}
+
+// createTXTRecordSet writes a brand new TXT record set, requiring that no
+// record set with this name/type exists yet (If-None-Match: *), so two
+// concurrent creates for the same FQDN can't silently overwrite each other.
+func (a *azureDnsProvider) createTXTRecordSet(ctx context.Context, zoneName, recordName string, values []string) error {
+	return a.writeTXTRecordSet(ctx, zoneName, recordName, values, "", "*")
+}
+
+func (a *azureDnsProvider) writeTXTRecordSet(ctx context.Context, zoneName, recordName string, values []string, ifMatch, ifNoneMatch string) error {
+	if a.zoneType == zoneTypePrivate {
+		var recs []privatedns.TxtRecord
+		for _, v := range values {
+			value := []string{v}
+			recs = append(recs, privatedns.TxtRecord{Value: &value})
+		}
+		set := privatedns.RecordSet{RecordSetProperties: &privatedns.RecordSetProperties{TTL: to.Int64Ptr(60), TxtRecords: &recs}}
+		_, err := a.privateRecordsClient.CreateOrUpdate(ctx, *a.resourceGroup, zoneName, privatedns.TXT, recordName, set, ifMatch, ifNoneMatch)
+		return err
+	}
+
+	var recs []adns.TxtRecord
+	for _, v := range values {
+		value := []string{v}
+		recs = append(recs, adns.TxtRecord{Value: &value})
+	}
+	set := adns.RecordSet{RecordSetProperties: &adns.RecordSetProperties{TTL: to.Int64Ptr(60), TxtRecords: &recs}}
+	_, err := a.recordsClient.CreateOrUpdate(ctx, *a.resourceGroup, zoneName, recordName, adns.TXT, set, ifMatch, ifNoneMatch)
+	return err
+}
+
+func (a *azureDnsProvider) deleteTXTRecordSet(ctx context.Context, zoneName, recordName, ifMatch string) error {
+	if a.zoneType == zoneTypePrivate {
+		_, err := a.privateRecordsClient.Delete(ctx, *a.resourceGroup, zoneName, privatedns.TXT, recordName, ifMatch)
+		if isNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	_, err := a.recordsClient.Delete(ctx, *a.resourceGroup, zoneName, recordName, adns.TXT, ifMatch)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func flattenPublicTXTValues(recs *[]adns.TxtRecord) []string {
+	if recs == nil {
+		return nil
+	}
+	var values []string
+	for _, r := range *recs {
+		if r.Value != nil {
+			values = append(values, *r.Value...)
+		}
+	}
+	return values
+}
+
+func flattenPrivateTXTValues(recs *[]privatedns.TxtRecord) []string {
+	if recs == nil {
+		return nil
+	}
+	var values []string
+	for _, r := range *recs {
+		if r.Value != nil {
+			values = append(values, *r.Value...)
+		}
+	}
+	return values
+}
+
+func etagString(etag *string) string {
+	if etag == nil {
+		return ""
+	}
+	return *etag
+}
+
+func isNotFound(err error) bool {
+	if detailed, ok := err.(autorest.DetailedError); ok {
+		return detailed.StatusCode == 404
+	}
+	return false
+}
+
+func isPreconditionFailed(err error) bool {
+	if detailed, ok := err.(autorest.DetailedError); ok {
+		return detailed.StatusCode == 412
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package azuredns
+
+import "testing"
+
+func TestSplitTXTFqdn(t *testing.T) {
+	a := &azureDnsProvider{
+		zones: map[string]*zoneInfo{
+			"example.com":     {name: "example.com"},
+			"sub.example.com": {name: "sub.example.com"},
+		},
+	}
+
+	cases := []struct {
+		name           string
+		fqdn           string
+		wantDomain     string
+		wantRecordName string
+		wantZoneName   string
+		wantErr        bool
+	}{
+		{
+			name:           "apex of a managed zone",
+			fqdn:           "example.com",
+			wantDomain:     "example.com",
+			wantRecordName: "@",
+			wantZoneName:   "example.com",
+		},
+		{
+			name:           "child label under the parent zone",
+			fqdn:           "_acme-challenge.example.com",
+			wantDomain:     "example.com",
+			wantRecordName: "_acme-challenge",
+			wantZoneName:   "example.com",
+		},
+		{
+			name:           "trailing dot is tolerated",
+			fqdn:           "_acme-challenge.example.com.",
+			wantDomain:     "example.com",
+			wantRecordName: "_acme-challenge",
+			wantZoneName:   "example.com",
+		},
+		{
+			name:           "most specific managed zone wins over its parent",
+			fqdn:           "_acme-challenge.sub.example.com",
+			wantDomain:     "sub.example.com",
+			wantRecordName: "_acme-challenge",
+			wantZoneName:   "sub.example.com",
+		},
+		{
+			name:           "apex of the more specific zone itself",
+			fqdn:           "sub.example.com",
+			wantDomain:     "sub.example.com",
+			wantRecordName: "@",
+			wantZoneName:   "sub.example.com",
+		},
+		{
+			name:    "no managed zone contains the fqdn",
+			fqdn:    "_acme-challenge.other.com",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			domain, recordName, zoneName, err := a.splitTXTFqdn(tc.fqdn)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("splitTXTFqdn(%q) = nil error, want error", tc.fqdn)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("splitTXTFqdn(%q) returned unexpected error: %v", tc.fqdn, err)
+			}
+			if domain != tc.wantDomain || recordName != tc.wantRecordName || zoneName != tc.wantZoneName {
+				t.Errorf("splitTXTFqdn(%q) = (%q, %q, %q), want (%q, %q, %q)",
+					tc.fqdn, domain, recordName, zoneName, tc.wantDomain, tc.wantRecordName, tc.wantZoneName)
+			}
+		})
+	}
+}
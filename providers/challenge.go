@@ -0,0 +1,17 @@
+package providers
+
+import "context"
+
+// DNSChallengeUpdater is implemented by providers that can perform a
+// read-modify-write update of a single TXT record set without going
+// through the full GetDomainCorrections diff cycle. It exists for ACME
+// DNS-01 solvers and similar tools that need to add or remove a single
+// challenge token concurrently with other callers touching the same
+// FQDN, rather than computing a whole-zone correction set.
+type DNSChallengeUpdater interface {
+	// UpdateTXTRecord performs a concurrency-safe read-modify-write of the
+	// TXT record set at fqdn. mutate receives the current set of TXT
+	// strings (empty if the record set doesn't exist yet) and returns the
+	// desired set; returning an empty slice deletes the record set.
+	UpdateTXTRecord(ctx context.Context, fqdn string, mutate func(existing []string) []string) error
+}